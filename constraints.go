@@ -0,0 +1,353 @@
+package protorand
+
+import (
+	"fmt"
+	"math"
+	"regexp/syntax"
+	"strings"
+
+	"github.com/envoyproxy/protoc-gen-validate/validate"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// ConstraintProvider inspects a field descriptor and optionally returns a
+// FieldConstraint describing how values for that field must be shaped.
+// Register one with RegisterConstraintProvider to support a validation
+// framework other than protoc-gen-validate (e.g. a hand-rolled annotation,
+// or protovalidate's buf.validate.field extension).
+type ConstraintProvider interface {
+	// FieldConstraint returns the constraint for fd, or nil if the provider
+	// has no opinion about it.
+	FieldConstraint(fd protoreflect.FieldDescriptor) FieldConstraint
+}
+
+// FieldConstraint samples a single value that satisfies a field's declared
+// constraints.
+type FieldConstraint interface {
+	// Generate produces a value for fd using p as the source of randomness.
+	Generate(p *ProtoRand, fd protoreflect.FieldDescriptor) (protoreflect.Value, error)
+}
+
+// RepeatedFieldConstraint is implemented by a FieldConstraint that also
+// wants to bound how many elements are generated for a repeated field,
+// taking precedence over the generic WithListLength range.
+type RepeatedFieldConstraint interface {
+	FieldConstraint
+	// ItemCount returns the inclusive element-count range to use, or
+	// ok == false if this constraint has no opinion on count.
+	ItemCount() (min, max int, ok bool)
+}
+
+// RegisterConstraintProvider adds cp to the list of providers consulted when
+// generating a value for a field. Providers are consulted in registration
+// order; the first one to return a non-nil FieldConstraint wins. The
+// protoc-gen-validate provider is registered by default.
+func (p *ProtoRand) RegisterConstraintProvider(cp ConstraintProvider) {
+	p.constraintProviders = append(p.constraintProviders, cp)
+}
+
+// fieldConstraint walks the registered providers and returns the first
+// applicable FieldConstraint, or nil if none apply.
+func (p *ProtoRand) fieldConstraint(fd protoreflect.FieldDescriptor) FieldConstraint {
+	for _, cp := range p.constraintProviders {
+		if fc := cp.FieldConstraint(fd); fc != nil {
+			return fc
+		}
+	}
+	return nil
+}
+
+// pgvConstraintProvider implements ConstraintProvider for
+// protoc-gen-validate's `validate.rules` field option.
+type pgvConstraintProvider struct{}
+
+func (pgvConstraintProvider) FieldConstraint(fd protoreflect.FieldDescriptor) FieldConstraint {
+	rules := pgvRules(fd)
+	if rules == nil {
+		return nil
+	}
+	return pgvFieldConstraint{rules: rules}
+}
+
+// pgvRules extracts the validate.FieldRules extension from fd, if present.
+func pgvRules(fd protoreflect.FieldDescriptor) *validate.FieldRules {
+	opts := fd.Options()
+	if opts == nil || !proto.HasExtension(opts, validate.E_Rules) {
+		return nil
+	}
+	rules, ok := proto.GetExtension(opts, validate.E_Rules).(*validate.FieldRules)
+	if !ok {
+		return nil
+	}
+	return rules
+}
+
+type pgvFieldConstraint struct {
+	rules *validate.FieldRules
+}
+
+func (c pgvFieldConstraint) Generate(p *ProtoRand, fd protoreflect.FieldDescriptor) (protoreflect.Value, error) {
+	switch r := c.rules.Type.(type) {
+	case *validate.FieldRules_Int32:
+		gte, lte := int64(math.MinInt32), int64(math.MaxInt32)
+		if r.Int32.Gte != nil {
+			gte = int64(r.Int32.GetGte())
+		}
+		if r.Int32.Lte != nil {
+			lte = int64(r.Int32.GetLte())
+		}
+		return protoreflect.ValueOfInt32(int32(p.randInt64Range(gte, lte))), nil
+	case *validate.FieldRules_Int64:
+		gte, lte := int64(math.MinInt64), int64(math.MaxInt64)
+		if r.Int64.Gte != nil {
+			gte = r.Int64.GetGte()
+		}
+		if r.Int64.Lte != nil {
+			lte = r.Int64.GetLte()
+		}
+		return protoreflect.ValueOfInt64(p.randInt64Range(gte, lte)), nil
+	case *validate.FieldRules_Uint32:
+		gte, lte := int64(0), int64(math.MaxUint32)
+		if r.Uint32.Gte != nil {
+			gte = int64(r.Uint32.GetGte())
+		}
+		if r.Uint32.Lte != nil {
+			lte = int64(r.Uint32.GetLte())
+		}
+		return protoreflect.ValueOfUint32(uint32(p.randInt64Range(gte, lte))), nil
+	case *validate.FieldRules_Uint64:
+		gte, lte := uint64(0), ^uint64(0)
+		if r.Uint64.Gte != nil {
+			gte = r.Uint64.GetGte()
+		}
+		if r.Uint64.Lte != nil {
+			lte = r.Uint64.GetLte()
+		}
+		return protoreflect.ValueOfUint64(p.randUint64Range(gte, lte)), nil
+	case *validate.FieldRules_Float:
+		gte, lte := float64(-math.MaxFloat32), float64(math.MaxFloat32)
+		if r.Float.Gte != nil {
+			gte = float64(r.Float.GetGte())
+		}
+		if r.Float.Lte != nil {
+			lte = float64(r.Float.GetLte())
+		}
+		return protoreflect.ValueOfFloat32(float32(p.randFloat64Range(gte, lte))), nil
+	case *validate.FieldRules_Double:
+		gte, lte := -math.MaxFloat64, math.MaxFloat64
+		if r.Double.Gte != nil {
+			gte = r.Double.GetGte()
+		}
+		if r.Double.Lte != nil {
+			lte = r.Double.GetLte()
+		}
+		return protoreflect.ValueOfFloat64(p.randFloat64Range(gte, lte)), nil
+	case *validate.FieldRules_String_:
+		return protoreflect.ValueOfString(p.randConstrainedString(r.String_)), nil
+	case *validate.FieldRules_Enum:
+		if r.Enum.GetDefinedOnly() {
+			return protoreflect.ValueOfEnum(p.chooseEnumValueRandomly(fd.Enum().Values())), nil
+		}
+	case *validate.FieldRules_Repeated:
+		// The element count itself is handled by ItemCount; here we only
+		// need to forward the per-element rules, if any, to generate the
+		// value for one element.
+		if items := r.Repeated.GetItems(); items != nil {
+			return pgvFieldConstraint{rules: items}.Generate(p, fd)
+		}
+	}
+	return protoreflect.Value{}, nil
+}
+
+// ItemCount implements RepeatedFieldConstraint by reading the
+// protoc-gen-validate repeated.min_items/max_items rules, if declared.
+func (c pgvFieldConstraint) ItemCount() (min, max int, ok bool) {
+	r, isRepeated := c.rules.Type.(*validate.FieldRules_Repeated)
+	if !isRepeated || (r.Repeated.MinItems == nil && r.Repeated.MaxItems == nil) {
+		return 0, 0, false
+	}
+	min = int(r.Repeated.GetMinItems())
+	max = int(r.Repeated.GetMaxItems())
+	if r.Repeated.MaxItems == nil {
+		max = min
+	}
+	return min, max, true
+}
+
+// randConstrainedString samples a string satisfying the declared
+// protoc-gen-validate string rules. For constraints it doesn't recognize it
+// falls back to a random string whose length honors min_len/max_len (0 to
+// len(Chars) when neither is declared), not the WithStringLength range.
+func (p *ProtoRand) randConstrainedString(r *validate.StringRules) string {
+	switch {
+	case r.GetUuid():
+		return p.randUUIDv4()
+	case r.GetEmail():
+		return p.randEmail()
+	case r.GetIpv4():
+		return p.randIPv4()
+	case r.GetIpv6():
+		return p.randIPv6()
+	case r.GetPattern() != "":
+		re, err := syntax.Parse(r.GetPattern(), syntax.Perl)
+		if err == nil {
+			minLen, maxLen := 1, 10
+			if r.MinLen != nil {
+				minLen = int(r.GetMinLen())
+			}
+			if r.MaxLen != nil {
+				maxLen = int(r.GetMaxLen())
+			} else if maxLen < minLen {
+				maxLen = minLen
+			}
+			if s, err := p.randMatchingRegexp(re, minLen, maxLen); err == nil {
+				return s
+			}
+		}
+	}
+
+	minLen, maxLen := 0, len(Chars)
+	if r.MinLen != nil {
+		minLen = int(r.GetMinLen())
+	}
+	if r.MaxLen != nil {
+		maxLen = int(r.GetMaxLen())
+	} else if maxLen < minLen {
+		maxLen = minLen + 10
+	}
+	return p.randStringRange(minLen, maxLen)
+}
+
+// randMatchingRegexp walks the parsed regular expression re to build a
+// matching string, then clamps it to a length in [minLen, maxLen] by
+// truncating it or padding it with random Chars; a clamped string may no
+// longer match re. It supports the common subset of syntax.Op used by
+// protoc-gen-validate patterns: literals, character classes, concatenation,
+// alternation, and the star/plus/quest/repeat quantifiers.
+func (p *ProtoRand) randMatchingRegexp(re *syntax.Regexp, minLen, maxLen int) (string, error) {
+	var b []rune
+	if err := p.appendRegexp(&b, re); err != nil {
+		return "", err
+	}
+	s := []rune(string(b))
+	if len(s) > maxLen {
+		s = s[:maxLen]
+	}
+	for len(s) < minLen {
+		s = append(s, Chars[p.rand.Intn(len(Chars))])
+	}
+	return string(s), nil
+}
+
+func (p *ProtoRand) appendRegexp(b *[]rune, re *syntax.Regexp) error {
+	switch re.Op {
+	case syntax.OpLiteral:
+		*b = append(*b, re.Rune...)
+	case syntax.OpCharClass:
+		*b = append(*b, p.randRuneFromClass(re.Rune))
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		*b = append(*b, Chars[p.rand.Intn(len(Chars))])
+	case syntax.OpCapture:
+		for _, sub := range re.Sub {
+			if err := p.appendRegexp(b, sub); err != nil {
+				return err
+			}
+		}
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			if err := p.appendRegexp(b, sub); err != nil {
+				return err
+			}
+		}
+	case syntax.OpAlternate:
+		if len(re.Sub) == 0 {
+			return nil
+		}
+		return p.appendRegexp(b, re.Sub[p.rand.Intn(len(re.Sub))])
+	case syntax.OpStar:
+		return p.appendRepeat(b, re.Sub[0], 0, 5)
+	case syntax.OpPlus:
+		return p.appendRepeat(b, re.Sub[0], 1, 5)
+	case syntax.OpQuest:
+		return p.appendRepeat(b, re.Sub[0], 0, 1)
+	case syntax.OpRepeat:
+		max := re.Max
+		if max < 0 {
+			max = re.Min + 5
+		}
+		return p.appendRepeat(b, re.Sub[0], re.Min, max)
+	case syntax.OpBeginLine, syntax.OpEndLine, syntax.OpBeginText, syntax.OpEndText, syntax.OpEmptyMatch:
+		// zero-width; nothing to emit
+	default:
+		return fmt.Errorf("protorand: unsupported regexp construct %v", re.Op)
+	}
+	return nil
+}
+
+func (p *ProtoRand) appendRepeat(b *[]rune, sub *syntax.Regexp, min, max int) error {
+	n := min
+	if max > min {
+		n = min + p.rand.Intn(max-min+1)
+	}
+	for i := 0; i < n; i++ {
+		if err := p.appendRegexp(b, sub); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *ProtoRand) randRuneFromClass(ranges []rune) rune {
+	if len(ranges) == 0 {
+		return Chars[p.rand.Intn(len(Chars))]
+	}
+	total := 0
+	for i := 0; i < len(ranges); i += 2 {
+		total += int(ranges[i+1]-ranges[i]) + 1
+	}
+	if total <= 0 {
+		return Chars[p.rand.Intn(len(Chars))]
+	}
+	n := p.rand.Intn(total)
+	for i := 0; i < len(ranges); i += 2 {
+		width := int(ranges[i+1]-ranges[i]) + 1
+		if n < width {
+			return ranges[i] + rune(n)
+		}
+		n -= width
+	}
+	return ranges[0]
+}
+
+func (p *ProtoRand) randUUIDv4() string {
+	b := make([]byte, 16)
+	for i := range b {
+		b[i] = byte(p.rand.Intn(256))
+	}
+	b[6] = (b[6] & 0x0f) | 0x40 // version 4
+	b[8] = (b[8] & 0x3f) | 0x80 // RFC 4122 variant
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// randIPv4 returns a random dotted-quad IPv4 address string.
+func (p *ProtoRand) randIPv4() string {
+	return fmt.Sprintf("%d.%d.%d.%d", p.rand.Intn(256), p.rand.Intn(256), p.rand.Intn(256), p.rand.Intn(256))
+}
+
+// randIPv6 returns a random IPv6 address string in full (non-compressed)
+// colon-hex form.
+func (p *ProtoRand) randIPv6() string {
+	groups := make([]string, 8)
+	for i := range groups {
+		groups[i] = fmt.Sprintf("%04x", p.rand.Intn(1<<16))
+	}
+	return strings.Join(groups, ":")
+}
+
+func (p *ProtoRand) randEmail() string {
+	local := p.randStringRange(3, 10)
+	domain := p.randStringRange(3, 10)
+	tld := []string{"com", "net", "org", "io"}[p.rand.Intn(4)]
+	return fmt.Sprintf("%s@%s.%s", local, domain, tld)
+}
@@ -0,0 +1,66 @@
+package protorand
+
+import (
+	"net"
+	"regexp"
+	"testing"
+
+	"github.com/envoyproxy/protoc-gen-validate/validate"
+)
+
+func TestRandUUIDv4(t *testing.T) {
+	pr := New()
+	re := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	for i := 0; i < 50; i++ {
+		if u := pr.randUUIDv4(); !re.MatchString(u) {
+			t.Fatalf("randUUIDv4() = %q, not a valid v4 UUID", u)
+		}
+	}
+}
+
+func TestRandIPv4(t *testing.T) {
+	pr := New()
+	for i := 0; i < 50; i++ {
+		s := pr.randIPv4()
+		if ip := net.ParseIP(s); ip == nil || ip.To4() == nil {
+			t.Fatalf("randIPv4() = %q, not a valid IPv4 address", s)
+		}
+	}
+}
+
+func TestRandIPv6(t *testing.T) {
+	pr := New()
+	for i := 0; i < 50; i++ {
+		s := pr.randIPv6()
+		if ip := net.ParseIP(s); ip == nil {
+			t.Fatalf("randIPv6() = %q, not a valid IP address", s)
+		}
+	}
+}
+
+func TestPGVOneSidedRangeVaries(t *testing.T) {
+	pr := New()
+	gte := int32(100)
+	rules := &validate.FieldRules{
+		Type: &validate.FieldRules_Int32{
+			Int32: &validate.Int32Rules{Gte: &gte},
+		},
+	}
+	fc := pgvFieldConstraint{rules: rules}
+
+	seen := map[int32]bool{}
+	for i := 0; i < 200; i++ {
+		v, err := fc.Generate(pr, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		n := int32(v.Int())
+		if n < 100 {
+			t.Fatalf("generated %d, below declared gte=100", n)
+		}
+		seen[n] = true
+	}
+	if len(seen) < 2 {
+		t.Fatalf("one-sided gte-only range never varied across 200 draws: %v", seen)
+	}
+}
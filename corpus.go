@@ -0,0 +1,219 @@
+package protorand
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"math/rand"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// GenFromBytes generates a message of the same type as in, deriving every
+// random decision deterministically from seed. The same (in, seed) pair
+// always produces the same message, regardless of wall-clock time or
+// however ProtoRand was last Seed-ed, which makes it suitable for driving Go
+// 1.18+ fuzz targets (testing.F): store the failing seed bytes in the
+// corpus and GenFromBytes reproduces the same failing message on replay.
+func (p *ProtoRand) GenFromBytes(in proto.Message, seed []byte) (proto.Message, error) {
+	saved := p.rand
+	p.rand = rand.New(newSeedSource(seed))
+	defer func() { p.rand = saved }()
+	return p.Gen(in)
+}
+
+// seedSource is a rand.Source that derives an effectively unbounded stream
+// of randomness from a fixed seed by hashing seed||counter with SHA-256 and
+// consuming 8 bytes of digest per Int63 call.
+type seedSource struct {
+	digest  [sha256.Size]byte
+	counter uint64
+}
+
+func newSeedSource(seed []byte) *seedSource {
+	return &seedSource{digest: sha256.Sum256(seed)}
+}
+
+func (s *seedSource) Int63() int64 {
+	var buf [sha256.Size + 8]byte
+	copy(buf[:], s.digest[:])
+	binary.BigEndian.PutUint64(buf[sha256.Size:], s.counter)
+	s.counter++
+	h := sha256.Sum256(buf[:])
+	return int64(binary.BigEndian.Uint64(h[:8]) &^ (1 << 63))
+}
+
+// Seed is a no-op: a seedSource's randomness is fixed at construction by
+// newSeedSource, and GenFromBytes never reseeds it.
+func (s *seedSource) Seed(int64) {}
+
+// Shrink repeatedly simplifies msg — halving list/map lengths, truncating
+// strings/bytes, and clearing leaf fields — for as long as predicate still
+// reports true, then returns the smallest message found. msg itself is left
+// untouched; the returned message is a separate clone.
+//
+// predicate typically re-runs whatever check found msg interesting in the
+// first place (e.g. "the server under test rejects this message"), so the
+// shrunk result is a minimal reproduction of the same failure.
+func Shrink(msg proto.Message, predicate func(proto.Message) bool) proto.Message {
+	root := proto.Clone(msg)
+	for shrinkMessage(root.ProtoReflect(), root, predicate) {
+		// keep making passes until one produces no further reduction
+	}
+	return root
+}
+
+// shrinkMessage makes one pass over m's populated fields, greedily keeping
+// any reduction that still satisfies predicate(root). It reports whether it
+// made at least one change.
+func shrinkMessage(m protoreflect.Message, root proto.Message, predicate func(proto.Message) bool) bool {
+	var fds []protoreflect.FieldDescriptor
+	m.Range(func(fd protoreflect.FieldDescriptor, _ protoreflect.Value) bool {
+		fds = append(fds, fd)
+		return true
+	})
+
+	changed := false
+	for _, fd := range fds {
+		switch {
+		case fd.IsList():
+			if shrinkList(m, fd, root, predicate) {
+				changed = true
+			}
+		case fd.IsMap():
+			if shrinkMap(m, fd, root, predicate) {
+				changed = true
+			}
+		case fd.Kind() == protoreflect.MessageKind:
+			if fd.Cardinality() != protoreflect.Required {
+				old := m.Get(fd)
+				m.Clear(fd)
+				if !predicate(root) {
+					m.Set(fd, old)
+				} else {
+					changed = true
+					continue
+				}
+			}
+			if shrinkMessage(m.Get(fd).Message(), root, predicate) {
+				changed = true
+			}
+		case fd.Kind() == protoreflect.StringKind:
+			if shrinkString(m, fd, root, predicate) {
+				changed = true
+			}
+		case fd.Kind() == protoreflect.BytesKind:
+			if shrinkBytes(m, fd, root, predicate) {
+				changed = true
+			}
+		default:
+			if fd.Cardinality() != protoreflect.Required {
+				old := m.Get(fd)
+				m.Clear(fd)
+				if !predicate(root) {
+					m.Set(fd, old)
+				} else {
+					changed = true
+				}
+			}
+		}
+	}
+	return changed
+}
+
+func shrinkList(m protoreflect.Message, fd protoreflect.FieldDescriptor, root proto.Message, predicate func(proto.Message) bool) bool {
+	changed := false
+	list := m.Mutable(fd).List()
+	if n := list.Len(); n > 0 {
+		half := n / 2
+		tail := make([]protoreflect.Value, n-half)
+		for i := half; i < n; i++ {
+			tail[i-half] = list.Get(i)
+		}
+		list.Truncate(half)
+		if !predicate(root) {
+			for _, v := range tail {
+				list.Append(v)
+			}
+		} else {
+			changed = true
+		}
+	}
+	if fd.Kind() == protoreflect.MessageKind {
+		list = m.Mutable(fd).List()
+		for i := 0; i < list.Len(); i++ {
+			if shrinkMessage(list.Get(i).Message(), root, predicate) {
+				changed = true
+			}
+		}
+	}
+	return changed
+}
+
+func shrinkMap(m protoreflect.Message, fd protoreflect.FieldDescriptor, root proto.Message, predicate func(proto.Message) bool) bool {
+	changed := false
+	mp := m.Mutable(fd).Map()
+
+	type entry struct {
+		key protoreflect.MapKey
+		val protoreflect.Value
+	}
+	var entries []entry
+	mp.Range(func(k protoreflect.MapKey, v protoreflect.Value) bool {
+		entries = append(entries, entry{k, v})
+		return true
+	})
+	if n := len(entries); n > 0 {
+		half := n / 2
+		removed := entries[half:]
+		for _, e := range removed {
+			mp.Clear(e.key)
+		}
+		if !predicate(root) {
+			for _, e := range removed {
+				mp.Set(e.key, e.val)
+			}
+		} else {
+			changed = true
+		}
+	}
+
+	if fd.MapValue().Kind() == protoreflect.MessageKind {
+		mp = m.Mutable(fd).Map()
+		mp.Range(func(_ protoreflect.MapKey, v protoreflect.Value) bool {
+			if shrinkMessage(v.Message(), root, predicate) {
+				changed = true
+			}
+			return true
+		})
+	}
+	return changed
+}
+
+func shrinkString(m protoreflect.Message, fd protoreflect.FieldDescriptor, root proto.Message, predicate func(proto.Message) bool) bool {
+	s := m.Get(fd).String()
+	if len(s) == 0 {
+		return false
+	}
+	old := s
+	m.Set(fd, protoreflect.ValueOfString(s[:len(s)/2]))
+	if !predicate(root) {
+		m.Set(fd, protoreflect.ValueOfString(old))
+		return false
+	}
+	return true
+}
+
+func shrinkBytes(m protoreflect.Message, fd protoreflect.FieldDescriptor, root proto.Message, predicate func(proto.Message) bool) bool {
+	b := m.Get(fd).Bytes()
+	if len(b) == 0 {
+		return false
+	}
+	old := append([]byte(nil), b...)
+	m.Set(fd, protoreflect.ValueOfBytes(b[:len(b)/2]))
+	if !predicate(root) {
+		m.Set(fd, protoreflect.ValueOfBytes(old))
+		return false
+	}
+	return true
+}
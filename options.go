@@ -0,0 +1,226 @@
+package protorand
+
+import (
+	"time"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+// FieldContext describes the field a custom generator is being asked to
+// produce a value for.
+type FieldContext struct {
+	// Path is the dotted field path from the root message being generated,
+	// e.g. "foo.bar[].baz" for the "baz" field of each element of a repeated
+	// "bar" nested inside "foo".
+	Path string
+	// Field is the descriptor of the field being generated.
+	Field protoreflect.FieldDescriptor
+	// Depth is the current recursion depth, where 0 is the root message.
+	Depth int
+}
+
+// FieldGeneratorFunc produces a value for the field described by ctx.
+type FieldGeneratorFunc func(ctx FieldContext) (protoreflect.Value, error)
+
+// config holds the tunables set via Option. Zero value is never used
+// directly; see defaultConfig.
+type config struct {
+	maxDepth int
+
+	stringLenMin, stringLenMax int
+	bytesLenMin, bytesLenMax   int
+	listLenMin, listLenMax     int
+	mapSizeMin, mapSizeMax     int
+
+	nullableProbability    float64
+	unknownEnumProbability float64
+
+	// presenceProbability and defaultValueProbability implement proto2/proto3
+	// explicit field-presence semantics. See WithPresenceProbability and
+	// WithDefaultValueProbability.
+	presenceProbability     float64
+	defaultValueProbability float64
+
+	// timestampMin/Max bound the window google.protobuf.Timestamp values are
+	// sampled from, as Unix seconds. See WithTimestampWindow.
+	timestampMin, timestampMax int64
+
+	// anyTypes is consulted to pick a concrete message type when generating
+	// a google.protobuf.Any. See WithAnyMessageTypes.
+	anyTypes *protoregistry.Types
+
+	fieldGenerators   map[string]FieldGeneratorFunc
+	messageGenerators map[protoreflect.FullName]FieldGeneratorFunc
+}
+
+func defaultConfig() config {
+	return config{
+		maxDepth:                MaxDepth,
+		stringLenMin:            10,
+		stringLenMax:            10,
+		bytesLenMin:             10,
+		bytesLenMax:             10,
+		listLenMin:              1,
+		listLenMax:              1,
+		mapSizeMin:              1,
+		mapSizeMax:              1,
+		nullableProbability:     1,
+		presenceProbability:     1,
+		defaultValueProbability: 0,
+		// 2001-09-09 to 2033-05-18, a reasonably "real-looking" default window.
+		timestampMin:      1_000_000_000,
+		timestampMax:      2_000_000_000,
+		anyTypes:          protoregistry.GlobalTypes,
+		fieldGenerators:   map[string]FieldGeneratorFunc{},
+		messageGenerators: map[protoreflect.FullName]FieldGeneratorFunc{},
+	}
+}
+
+// Option configures a ProtoRand created by New.
+type Option func(*config)
+
+// WithMaxDepth overrides the maximum recursion depth used to guard against
+// (directly or transitively) self-referential messages. The default is
+// MaxDepth.
+func WithMaxDepth(depth int) Option {
+	return func(c *config) {
+		c.maxDepth = depth
+	}
+}
+
+// WithStringLength sets the inclusive range of lengths used when generating
+// string fields. The default is a fixed length of 10.
+func WithStringLength(min, max int) Option {
+	return func(c *config) {
+		c.stringLenMin = min
+		c.stringLenMax = max
+	}
+}
+
+// WithBytesLength sets the inclusive range of lengths used when generating
+// bytes fields. The default is a fixed length of 10.
+func WithBytesLength(min, max int) Option {
+	return func(c *config) {
+		c.bytesLenMin = min
+		c.bytesLenMax = max
+	}
+}
+
+// WithListLength sets the inclusive range of element counts used when
+// generating repeated fields. The default is a fixed length of 1. Setting
+// min to 0 makes empty lists reachable, which matters for exercising code
+// paths that special-case empty collections.
+func WithListLength(min, max int) Option {
+	return func(c *config) {
+		c.listLenMin = min
+		c.listLenMax = max
+	}
+}
+
+// WithMapSize sets the inclusive range of entry counts used when generating
+// map fields. The default is a fixed size of 1. Setting min to 0 makes empty
+// maps reachable. The actual size may come out smaller than requested if
+// random key collisions exhaust the generator's retry budget.
+func WithMapSize(min, max int) Option {
+	return func(c *config) {
+		c.mapSizeMin = min
+		c.mapSizeMax = max
+	}
+}
+
+// WithNullableProbability sets the probability, in [0, 1], that a singular
+// message-kind field is populated rather than left unset. The default is 1,
+// i.e. message fields are always populated.
+func WithNullableProbability(prob float64) Option {
+	return func(c *config) {
+		c.nullableProbability = prob
+	}
+}
+
+// WithTimestampWindow sets the window that google.protobuf.Timestamp values
+// are sampled from. The default window is 2001-09-09 to 2033-05-18.
+func WithTimestampWindow(min, max time.Time) Option {
+	return func(c *config) {
+		c.timestampMin = min.Unix()
+		c.timestampMax = max.Unix()
+	}
+}
+
+// WithAnyMessageTypes sets the registry consulted to pick a concrete message
+// type when generating a google.protobuf.Any. The default is
+// protoregistry.GlobalTypes.
+func WithAnyMessageTypes(types *protoregistry.Types) Option {
+	return func(c *config) {
+		c.anyTypes = types
+	}
+}
+
+// WithUnknownEnumProbability sets the probability, in [0, 1], that a proto3
+// (open) enum field is populated with an out-of-range EnumNumber instead of
+// one of its declared values. This is useful for exercising the
+// forward-compatibility code paths clients need when they receive an enum
+// value they don't recognize. The default is 0. Proto2 (closed) enums are
+// unaffected.
+func WithUnknownEnumProbability(prob float64) Option {
+	return func(c *config) {
+		c.unknownEnumProbability = prob
+	}
+}
+
+// WithPresenceProbability sets the probability, in [0, 1], that a field
+// with explicit presence (proto2 `optional`, proto3 `optional`, or a oneof
+// member) is populated rather than left unset. Required fields are always
+// populated regardless of this setting. Singular message-kind fields are not
+// affected; their presence is governed by WithNullableProbability alone. The
+// default is 1.
+func WithPresenceProbability(prob float64) Option {
+	return func(c *config) {
+		c.presenceProbability = prob
+	}
+}
+
+// WithDefaultValueProbability sets the probability, in [0, 1], that a field
+// with a declared proto2 default value is left unset so that Get() returns
+// that default, instead of being populated with a random value. The default
+// is 0, i.e. fields with a default are always explicitly populated.
+func WithDefaultValueProbability(prob float64) Option {
+	return func(c *config) {
+		c.defaultValueProbability = prob
+	}
+}
+
+// WithFieldGenerator registers gen as the generator for the field at path, a
+// dotted field path from the root message (e.g. "foo.bar[].baz" for the
+// "baz" field of each element of the repeated "bar" nested inside "foo").
+// It takes precedence over WithMessageGenerator and any built-in generation
+// for that field.
+func WithFieldGenerator(path string, gen FieldGeneratorFunc) Option {
+	return func(c *config) {
+		c.fieldGenerators[path] = gen
+	}
+}
+
+// WithMessageGenerator registers gen as the generator used for every field
+// whose message type is fullName, regardless of where it appears in the
+// tree. A field-specific generator registered via WithFieldGenerator takes
+// precedence over this.
+func WithMessageGenerator(fullName protoreflect.FullName, gen FieldGeneratorFunc) Option {
+	return func(c *config) {
+		c.messageGenerators[fullName] = gen
+	}
+}
+
+// childPath builds the dotted path of a field nested under parent.
+func childPath(parent string, name protoreflect.Name) string {
+	if parent == "" {
+		return string(name)
+	}
+	return parent + "." + string(name)
+}
+
+// elemPath builds the dotted path of the repeated element or map value
+// nested under a field at path.
+func elemPath(path string) string {
+	return path + "[]"
+}
@@ -0,0 +1,105 @@
+package protorand
+
+import (
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// testOuterDescriptor builds a small synthetic descriptor for:
+//
+//	message Inner { string name = 1; }
+//	message Outer { repeated Inner items = 1; string label = 2; }
+//
+// so options can be exercised against a nested/repeated field path without
+// depending on a generated .pb.go file.
+func testOuterDescriptor(t *testing.T) protoreflect.MessageDescriptor {
+	t.Helper()
+	fdProto := &descriptorpb.FileDescriptorProto{
+		Name:    proto.String("protorand/options_test.proto"),
+		Package: proto.String("protorand.optionstest"),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{
+			{
+				Name: proto.String("Inner"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					{
+						Name:   proto.String("name"),
+						Number: proto.Int32(1),
+						Label:  descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+						Type:   descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+					},
+				},
+			},
+			{
+				Name: proto.String("Outer"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					{
+						Name:     proto.String("items"),
+						Number:   proto.Int32(1),
+						Label:    descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum(),
+						Type:     descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum(),
+						TypeName: proto.String(".protorand.optionstest.Inner"),
+					},
+					{
+						Name:   proto.String("label"),
+						Number: proto.Int32(2),
+						Label:  descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+						Type:   descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+					},
+				},
+			},
+		},
+	}
+	file, err := protodesc.NewFile(fdProto, protoregistry.GlobalFiles)
+	if err != nil {
+		t.Fatalf("protodesc.NewFile: %v", err)
+	}
+	return file.Messages().ByName("Outer")
+}
+
+func TestWithFieldGeneratorOverridesNestedRepeatedPath(t *testing.T) {
+	mds := testOuterDescriptor(t)
+	pr := New(
+		WithListLength(3, 3),
+		WithFieldGenerator("items[].name", func(ctx FieldContext) (protoreflect.Value, error) {
+			return protoreflect.ValueOfString("overridden"), nil
+		}),
+	)
+
+	dm, err := pr.NewDynamicProtoRand(mds)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	items := dm.Get(mds.Fields().ByName("items")).List()
+	if items.Len() != 3 {
+		t.Fatalf("got %d items, want 3", items.Len())
+	}
+	nameField := mds.Fields().ByName("items").Message().Fields().ByName("name")
+	for i := 0; i < items.Len(); i++ {
+		got := items.Get(i).Message().Get(nameField).String()
+		if got != "overridden" {
+			t.Fatalf("items[%d].name = %q, want %q from the registered field generator", i, got, "overridden")
+		}
+	}
+}
+
+func TestWithStringLengthConstrainsOutput(t *testing.T) {
+	mds := testOuterDescriptor(t)
+	pr := New(WithStringLength(5, 5), WithListLength(0, 0))
+
+	dm, err := pr.NewDynamicProtoRand(mds)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	label := dm.Get(mds.Fields().ByName("label")).String()
+	if len(label) != 5 {
+		t.Fatalf("label = %q (len %d), want length 5 per WithStringLength(5, 5)", label, len(label))
+	}
+}
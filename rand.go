@@ -25,12 +25,28 @@ var (
 // ProtoRand is a source of random values for protobuf fields.
 type ProtoRand struct {
 	rand *rand.Rand
+
+	config config
+
+	// constraintProviders are consulted, in order, before falling back to
+	// unconstrained generation. See RegisterConstraintProvider.
+	constraintProviders []ConstraintProvider
 }
 
-// New creates a new ProtoRand.
-func New() *ProtoRand {
+// New creates a new ProtoRand. By default it populates every field with a
+// random value, honoring any protoc-gen-validate rules declared on the field
+// (the constraint provider for them is registered by default); pass Option
+// values to customize field/message
+// generation, lengths, recursion depth, and more.
+func New(opts ...Option) *ProtoRand {
+	cfg := defaultConfig()
+	for _, opt := range opts {
+		opt(&cfg)
+	}
 	return &ProtoRand{
-		rand: rand.New(rand.NewSource(time.Now().UnixNano())),
+		rand:                rand.New(rand.NewSource(time.Now().UnixNano())),
+		config:              cfg,
+		constraintProviders: []ConstraintProvider{pgvConstraintProvider{}},
 	}
 }
 
@@ -56,11 +72,43 @@ func (p *ProtoRand) Gen(in proto.Message) (proto.Message, error) {
 
 // NewDynamicProtoRand creates dynamicpb with assigning random values to a proto.
 func (p *ProtoRand) NewDynamicProtoRand(mds protoreflect.MessageDescriptor) (*dynamicpb.Message, error) {
-	return p.newDynamicProtoRand(mds, MaxDepth)
+	return p.newDynamicProtoRand(mds, p.config.maxDepth, "", nil)
 }
 
-func (p *ProtoRand) newDynamicProtoRand(mds protoreflect.MessageDescriptor, allowedDepth int) (*dynamicpb.Message, error) {
-	getRandValue := func(fd protoreflect.FieldDescriptor) (protoreflect.Value, error) {
+// newDynamicProtoRand generates mds, special-casing it first if it is itself
+// a google.protobuf well-known type (this is what lets Gen(&durationpb.Duration{})
+// and similarly-rooted messages pick up the WKT handlers, not just WKT
+// fields nested inside some other message). parent is the descriptor of the
+// message that contains the field mds is being generated for (nil at the
+// root), used by the FieldMask handler to pick sibling field names.
+func (p *ProtoRand) newDynamicProtoRand(mds protoreflect.MessageDescriptor, allowedDepth int, path string, parent protoreflect.MessageDescriptor) (*dynamicpb.Message, error) {
+	if wkm, ok, err := p.wellKnownMessage(mds, parent, allowedDepth); err != nil {
+		return nil, err
+	} else if ok {
+		return wkm, nil
+	}
+
+	depth := p.config.maxDepth - allowedDepth
+
+	getRandValue := func(fd protoreflect.FieldDescriptor, fieldPath string) (protoreflect.Value, error) {
+		if gen, ok := p.config.fieldGenerators[fieldPath]; ok {
+			return gen(FieldContext{Path: fieldPath, Field: fd, Depth: depth})
+		}
+		if fd.Kind() == protoreflect.MessageKind {
+			if gen, ok := p.config.messageGenerators[fd.Message().FullName()]; ok {
+				return gen(FieldContext{Path: fieldPath, Field: fd, Depth: depth})
+			}
+		}
+		if fc := p.fieldConstraint(fd); fc != nil {
+			if v, err := fc.Generate(p, fd); err != nil {
+				return protoreflect.Value{}, err
+			} else if v.IsValid() {
+				return v, nil
+			}
+			// constraint had no opinion on this value (e.g. unrecognized
+			// rule type); fall through to unconstrained generation.
+		}
+
 		switch fd.Kind() {
 		case protoreflect.Int32Kind:
 			return protoreflect.ValueOfInt32(p.randInt32()), nil
@@ -91,13 +139,18 @@ func (p *ProtoRand) newDynamicProtoRand(mds protoreflect.MessageDescriptor, allo
 		case protoreflect.BoolKind:
 			return protoreflect.ValueOfBool(p.randBool()), nil
 		case protoreflect.EnumKind:
+			if fd.Enum().ParentFile().Syntax() == protoreflect.Proto3 && p.rand.Float64() < p.config.unknownEnumProbability {
+				// emit an out-of-range value to exercise forward-compatibility
+				// handling for this open enum
+				return protoreflect.ValueOfEnum(protoreflect.EnumNumber(p.rand.Int31())), nil
+			}
 			return protoreflect.ValueOfEnum(p.chooseEnumValueRandomly(fd.Enum().Values())), nil
 		case protoreflect.BytesKind:
 			return protoreflect.ValueOfBytes(p.randBytes()), nil
 		case protoreflect.MessageKind:
 			// process recursively (if we have more stacks to give...)
 			if allowedDepth > 0 {
-				rm, err := p.newDynamicProtoRand(fd.Message(), allowedDepth-1)
+				rm, err := p.newDynamicProtoRand(fd.Message(), allowedDepth-1, fieldPath, mds)
 				if err != nil {
 					return protoreflect.Value{}, err
 				}
@@ -131,34 +184,77 @@ func (p *ProtoRand) newDynamicProtoRand(mds protoreflect.MessageDescriptor, allo
 			}
 		}
 
+		fieldPath := childPath(path, fd.Name())
+
 		if fd.IsList() {
 			list := dm.Mutable(fd).List()
-			// TODO: decide the number of elements randomly
-			value, err := getRandValue(fd)
-			if err != nil {
-				return nil, err
+			listLenMin, listLenMax := p.config.listLenMin, p.config.listLenMax
+			if rc, ok := p.fieldConstraint(fd).(RepeatedFieldConstraint); ok {
+				if min, max, ok := rc.ItemCount(); ok {
+					listLenMin, listLenMax = min, max
+				}
+			}
+			count := p.randCount(listLenMin, listLenMax)
+			for i := 0; i < count; i++ {
+				value, err := getRandValue(fd, elemPath(fieldPath))
+				if err != nil {
+					return nil, err
+				}
+				list.Append(value)
 			}
-			list.Append(value)
 			dm.Set(fd, protoreflect.ValueOfList(list))
 			continue
 		}
 		if fd.IsMap() {
 			mp := dm.Mutable(fd).Map()
-			// TODO: make the number of elements randomly
-			key, err := getRandValue(fd.MapKey())
-			if err != nil {
-				return nil, err
-			}
-			value, err := getRandValue(fd.MapValue())
-			if err != nil {
-				return nil, err
+			count := p.randCount(p.config.mapSizeMin, p.config.mapSizeMax)
+			// Guard against duplicate keys colliding before we reach the
+			// requested size: retry a bounded number of times, then settle
+			// for however many distinct keys we found.
+			maxAttempts := count*4 + 4
+			for attempts := 0; mp.Len() < count && attempts < maxAttempts; attempts++ {
+				key, err := getRandValue(fd.MapKey(), fieldPath+".key")
+				if err != nil {
+					return nil, err
+				}
+				mapKey := protoreflect.MapKey(key)
+				if mp.Has(mapKey) {
+					continue
+				}
+				value, err := getRandValue(fd.MapValue(), elemPath(fieldPath))
+				if err != nil {
+					return nil, err
+				}
+				mp.Set(mapKey, value)
 			}
-			mp.Set(protoreflect.MapKey(key), value)
 			dm.Set(fd, protoreflect.ValueOfMap(mp))
 			continue
 		}
 
-		value, err := getRandValue(fd)
+		required := fd.Cardinality() == protoreflect.Required
+		leaveUnset := false
+		if !required {
+			switch {
+			case fd.Kind() == protoreflect.MessageKind:
+				// Message-kind fields always report HasPresence() == true, so
+				// nullableProbability is their one presence decision: layering
+				// presenceProbability on top as well would silently override
+				// WithNullableProbability(1)'s "always populated" guarantee.
+				if p.rand.Float64() > p.config.nullableProbability {
+					leaveUnset = true
+				}
+			case fd.HasDefault() && p.rand.Float64() < p.config.defaultValueProbability:
+				// leave unset so Get() returns the field's declared default
+				leaveUnset = true
+			case fd.HasPresence() && p.rand.Float64() > p.config.presenceProbability:
+				leaveUnset = true
+			}
+		}
+		if leaveUnset {
+			continue
+		}
+
+		value, err := getRandValue(fd, fieldPath)
 		if err != nil {
 			return nil, err
 		}
@@ -193,29 +289,91 @@ func (p *ProtoRand) randFloat64() float64 {
 }
 
 func (p *ProtoRand) randBytes() []byte {
-	return []byte(p.randString())
+	return []byte(p.randStringRange(p.config.bytesLenMin, p.config.bytesLenMax))
+}
+
+// randCount returns a random element count uniformly sampled from
+// [min, max]. If max < min, min is returned.
+func (p *ProtoRand) randCount(min, max int) int {
+	if max <= min {
+		return min
+	}
+	return min + p.rand.Intn(max-min+1)
 }
 
 func (p *ProtoRand) randString() string {
-	b := make([]rune, 10) // TODO: make the length randomly or use a predefined length?
+	return p.randStringRange(p.config.stringLenMin, p.config.stringLenMax)
+}
+
+// randStringRange returns a random string whose length is uniformly sampled
+// from [minLen, maxLen].
+func (p *ProtoRand) randStringRange(minLen, maxLen int) string {
+	if maxLen < minLen {
+		maxLen = minLen
+	}
+	n := minLen
+	if maxLen > minLen {
+		n = minLen + p.rand.Intn(maxLen-minLen+1)
+	}
+	b := make([]rune, n)
 	for i := range b {
 		b[i] = Chars[p.rand.Intn(len(Chars))]
 	}
 	return string(b)
 }
 
+// randInt64Range returns a random int64 uniformly sampled from [gte, lte].
+// If lte < gte, gte is returned.
+func (p *ProtoRand) randInt64Range(gte, lte int64) int64 {
+	if lte <= gte {
+		return gte
+	}
+	span := lte - gte
+	if span < 0 {
+		// overflowed int64; sample the span in unsigned arithmetic instead
+		return gte + int64(p.randUint64Range(0, uint64(lte)-uint64(gte)))
+	}
+	return gte + p.rand.Int63n(span+1)
+}
+
+// randUint64Range returns a random uint64 uniformly sampled from [gte, lte].
+// If lte < gte, gte is returned. Unlike randInt64Range, the span is computed
+// in unsigned arithmetic so bounds that straddle 1<<63 (representable as
+// uint64 but not as int64) still work.
+func (p *ProtoRand) randUint64Range(gte, lte uint64) uint64 {
+	if lte <= gte {
+		return gte
+	}
+	span := lte - gte
+	if span == ^uint64(0) {
+		// full range; fall back to unconstrained sampling
+		return p.randUint64()
+	}
+	return gte + p.rand.Uint64()%(span+1)
+}
+
+// randFloat64Range returns a random float64 uniformly sampled from [gte, lte].
+func (p *ProtoRand) randFloat64Range(gte, lte float64) float64 {
+	if lte <= gte {
+		return gte
+	}
+	// interpolate rather than computing lte-gte, which overflows to +Inf for
+	// bounds such as [-math.MaxFloat64, math.MaxFloat64]
+	f := p.rand.Float64()
+	return gte*(1-f) + lte*f
+}
+
 func (p *ProtoRand) randBool() bool {
 	return p.rand.Int31()%2 == 0
 }
 
 func (p *ProtoRand) chooseEnumValueRandomly(values protoreflect.EnumValueDescriptors) protoreflect.EnumNumber {
 	ln := values.Len()
-	if ln <= 1 {
+	if ln == 0 {
 		return 0
 	}
 
-	value := values.Get(p.rand.Intn(ln - 1))
-	return value.Number()
+	return values.Get(p.rand.Intn(ln)).Number()
 }
 
 func (p *ProtoRand) chooseOneOfFieldRandomly(oneOf protoreflect.OneofDescriptor) protoreflect.FieldDescriptor {
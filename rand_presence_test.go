@@ -0,0 +1,129 @@
+package protorand
+
+import (
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// testProto2Descriptor builds a synthetic proto2 descriptor for:
+//
+//	message Inner { optional string name = 1; }
+//	message Proto2Msg {
+//	  required string req = 1;
+//	  optional int32 num = 2 [default = 42];
+//	  optional Inner inner = 3;
+//	}
+func testProto2Descriptor(t *testing.T) protoreflect.MessageDescriptor {
+	t.Helper()
+	label := descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum()
+	fdProto := &descriptorpb.FileDescriptorProto{
+		Name:    proto.String("protorand/rand_presence_test.proto"),
+		Package: proto.String("protorand.presencetest"),
+		Syntax:  proto.String("proto2"),
+		MessageType: []*descriptorpb.DescriptorProto{
+			{
+				Name: proto.String("Inner"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					{
+						Name:   proto.String("name"),
+						Number: proto.Int32(1),
+						Label:  label,
+						Type:   descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+					},
+				},
+			},
+			{
+				Name: proto.String("Proto2Msg"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					{
+						Name:   proto.String("req"),
+						Number: proto.Int32(1),
+						Label:  descriptorpb.FieldDescriptorProto_LABEL_REQUIRED.Enum(),
+						Type:   descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+					},
+					{
+						Name:         proto.String("num"),
+						Number:       proto.Int32(2),
+						Label:        label,
+						Type:         descriptorpb.FieldDescriptorProto_TYPE_INT32.Enum(),
+						DefaultValue: proto.String("42"),
+					},
+					{
+						Name:     proto.String("inner"),
+						Number:   proto.Int32(3),
+						Label:    label,
+						Type:     descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum(),
+						TypeName: proto.String(".protorand.presencetest.Inner"),
+					},
+				},
+			},
+		},
+	}
+	file, err := protodesc.NewFile(fdProto, protoregistry.GlobalFiles)
+	if err != nil {
+		t.Fatalf("protodesc.NewFile: %v", err)
+	}
+	return file.Messages().ByName("Proto2Msg")
+}
+
+func TestProto2RequiredAlwaysSet(t *testing.T) {
+	mds := testProto2Descriptor(t)
+	pr := New(WithPresenceProbability(0), WithNullableProbability(0), WithDefaultValueProbability(1))
+
+	for i := 0; i < 20; i++ {
+		dm, err := pr.NewDynamicProtoRand(mds)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !dm.Has(mds.Fields().ByName("req")) {
+			t.Fatal("required field \"req\" was left unset despite Cardinality() == Required")
+		}
+	}
+}
+
+func TestWithNullableProbabilityOverridesPresenceForMessageFields(t *testing.T) {
+	mds := testProto2Descriptor(t)
+	pr := New(WithNullableProbability(1), WithPresenceProbability(0))
+
+	for i := 0; i < 50; i++ {
+		dm, err := pr.NewDynamicProtoRand(mds)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !dm.Has(mds.Fields().ByName("inner")) {
+			t.Fatal("message field \"inner\" was left unset even though WithNullableProbability(1) promises message fields are always populated")
+		}
+	}
+}
+
+func TestWithDefaultValueProbabilityLeavesFieldUnset(t *testing.T) {
+	mds := testProto2Descriptor(t)
+	numField := mds.Fields().ByName("num")
+
+	always := New(WithDefaultValueProbability(1))
+	for i := 0; i < 20; i++ {
+		dm, err := always.NewDynamicProtoRand(mds)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if dm.Has(numField) {
+			t.Fatal("WithDefaultValueProbability(1) should always leave a defaulted field unset")
+		}
+	}
+
+	never := New(WithDefaultValueProbability(0))
+	for i := 0; i < 20; i++ {
+		dm, err := never.NewDynamicProtoRand(mds)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !dm.Has(numField) {
+			t.Fatal("WithDefaultValueProbability(0) should always populate a defaulted field")
+		}
+	}
+}
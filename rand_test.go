@@ -0,0 +1,99 @@
+package protorand
+
+import (
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+func TestRandUint64Range(t *testing.T) {
+	pr := New()
+
+	if got := pr.randUint64Range(5, 5); got != 5 {
+		t.Errorf("randUint64Range(5, 5) = %d, want 5", got)
+	}
+	if got := pr.randUint64Range(5, 3); got != 5 {
+		t.Errorf("randUint64Range(5, 3) = %d, want 5 (lte < gte falls back to gte)", got)
+	}
+
+	// Bounds straddling 1<<63 are representable in uint64 but not int64;
+	// make sure sampling doesn't get stuck always returning gte.
+	gte, lte := uint64(9223372036854775800), uint64(9223372036854775820)
+	seen := map[uint64]bool{}
+	for i := 0; i < 200; i++ {
+		v := pr.randUint64Range(gte, lte)
+		if v < gte || v > lte {
+			t.Fatalf("randUint64Range(%d, %d) = %d, out of range", gte, lte, v)
+		}
+		seen[v] = true
+	}
+	if len(seen) < 2 {
+		t.Fatalf("randUint64Range(%d, %d) never varied across 200 draws: %v", gte, lte, seen)
+	}
+}
+
+func TestChooseEnumValueRandomlyReachesLastValue(t *testing.T) {
+	fdProto := &descriptorpb.FileDescriptorProto{
+		Name:    proto.String("protorand/rand_enum_multi_test.proto"),
+		Package: proto.String("protorand.enumtest"),
+		Syntax:  proto.String("proto3"),
+		EnumType: []*descriptorpb.EnumDescriptorProto{
+			{
+				Name: proto.String("Color"),
+				Value: []*descriptorpb.EnumValueDescriptorProto{
+					{Name: proto.String("RED"), Number: proto.Int32(0)},
+					{Name: proto.String("GREEN"), Number: proto.Int32(1)},
+					{Name: proto.String("BLUE"), Number: proto.Int32(2)},
+				},
+			},
+		},
+	}
+	file, err := protodesc.NewFile(fdProto, protoregistry.GlobalFiles)
+	if err != nil {
+		t.Fatalf("protodesc.NewFile: %v", err)
+	}
+	values := file.Enums().Get(0).Values()
+
+	pr := New()
+	seenLast := false
+	for i := 0; i < 500; i++ {
+		if pr.chooseEnumValueRandomly(values) == values.Get(values.Len()-1).Number() {
+			seenLast = true
+			break
+		}
+	}
+	if !seenLast {
+		t.Fatalf("chooseEnumValueRandomly never sampled the last declared value (%v) across 500 draws", values.Get(values.Len()-1).Number())
+	}
+}
+
+func TestChooseEnumValueRandomlySingleValue(t *testing.T) {
+	fdProto := &descriptorpb.FileDescriptorProto{
+		Name:    proto.String("protorand/rand_enum_single_test.proto"),
+		Package: proto.String("protorand.enumtest"),
+		Syntax:  proto.String("proto2"),
+		EnumType: []*descriptorpb.EnumDescriptorProto{
+			{
+				Name: proto.String("Status"),
+				Value: []*descriptorpb.EnumValueDescriptorProto{
+					{Name: proto.String("ACTIVE"), Number: proto.Int32(5)},
+				},
+			},
+		},
+	}
+	file, err := protodesc.NewFile(fdProto, protoregistry.GlobalFiles)
+	if err != nil {
+		t.Fatalf("protodesc.NewFile: %v", err)
+	}
+	values := file.Enums().Get(0).Values()
+
+	pr := New()
+	for i := 0; i < 10; i++ {
+		if got := pr.chooseEnumValueRandomly(values); got != 5 {
+			t.Fatalf("chooseEnumValueRandomly() on a single-value enum = %d, want 5", got)
+		}
+	}
+}
@@ -0,0 +1,252 @@
+package protorand
+
+import (
+	"fmt"
+	"sort"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// Full names of the google.protobuf well-known types that get special-cased
+// generation instead of being recursed into blindly.
+const (
+	wktTimestamp = protoreflect.FullName("google.protobuf.Timestamp")
+	wktDuration  = protoreflect.FullName("google.protobuf.Duration")
+	wktFieldMask = protoreflect.FullName("google.protobuf.FieldMask")
+	wktAny       = protoreflect.FullName("google.protobuf.Any")
+	wktStruct    = protoreflect.FullName("google.protobuf.Struct")
+	wktValue     = protoreflect.FullName("google.protobuf.Value")
+	wktListValue = protoreflect.FullName("google.protobuf.ListValue")
+	wktEmpty     = protoreflect.FullName("google.protobuf.Empty")
+
+	wktStringValue = protoreflect.FullName("google.protobuf.StringValue")
+	wktBytesValue  = protoreflect.FullName("google.protobuf.BytesValue")
+	wktBoolValue   = protoreflect.FullName("google.protobuf.BoolValue")
+	wktInt32Value  = protoreflect.FullName("google.protobuf.Int32Value")
+	wktInt64Value  = protoreflect.FullName("google.protobuf.Int64Value")
+	wktUInt32Value = protoreflect.FullName("google.protobuf.UInt32Value")
+	wktUInt64Value = protoreflect.FullName("google.protobuf.UInt64Value")
+	wktFloatValue  = protoreflect.FullName("google.protobuf.FloatValue")
+	wktDoubleValue = protoreflect.FullName("google.protobuf.DoubleValue")
+)
+
+// wellKnownMessage reports whether mds is one of the google.protobuf
+// well-known types that this package special-cases, and if so generates a
+// value for it. parent is the descriptor of the message containing the
+// field being generated (used by FieldMask to pick sibling field names).
+func (p *ProtoRand) wellKnownMessage(mds protoreflect.MessageDescriptor, parent protoreflect.MessageDescriptor, allowedDepth int) (*dynamicpb.Message, bool, error) {
+	switch mds.FullName() {
+	case wktTimestamp:
+		return p.genTimestamp(mds), true, nil
+	case wktDuration:
+		return p.genDuration(mds), true, nil
+	case wktFieldMask:
+		return p.genFieldMask(mds, parent), true, nil
+	case wktAny:
+		m, err := p.genAny(mds, allowedDepth)
+		return m, true, err
+	case wktStruct:
+		return p.genStruct(mds, allowedDepth), true, nil
+	case wktValue:
+		return p.genValue(mds, allowedDepth), true, nil
+	case wktListValue:
+		return p.genListValue(mds, allowedDepth), true, nil
+	case wktEmpty:
+		return dynamicpb.NewMessage(mds), true, nil
+	case wktStringValue, wktBytesValue, wktBoolValue, wktInt32Value, wktInt64Value,
+		wktUInt32Value, wktUInt64Value, wktFloatValue, wktDoubleValue:
+		m, err := p.genWrapper(mds)
+		return m, true, err
+	default:
+		return nil, false, nil
+	}
+}
+
+func (p *ProtoRand) genTimestamp(mds protoreflect.MessageDescriptor) *dynamicpb.Message {
+	m := dynamicpb.NewMessage(mds)
+	seconds := p.randInt64Range(p.config.timestampMin, p.config.timestampMax)
+	m.Set(mds.Fields().ByName("seconds"), protoreflect.ValueOfInt64(seconds))
+	m.Set(mds.Fields().ByName("nanos"), protoreflect.ValueOfInt32(p.rand.Int31n(1_000_000_000)))
+	return m
+}
+
+func (p *ProtoRand) genDuration(mds protoreflect.MessageDescriptor) *dynamicpb.Message {
+	m := dynamicpb.NewMessage(mds)
+	seconds := p.randInt64Range(-p.config.timestampMax, p.config.timestampMax)
+	nanos := p.rand.Int31n(1_000_000_000)
+	// A valid Duration requires seconds and nanos to carry the same sign
+	// (or be zero); a negative seconds with positive nanos (or vice versa)
+	// doesn't round-trip through google.protobuf.Duration's own semantics.
+	if seconds < 0 {
+		nanos = -nanos
+	}
+	m.Set(mds.Fields().ByName("seconds"), protoreflect.ValueOfInt64(seconds))
+	m.Set(mds.Fields().ByName("nanos"), protoreflect.ValueOfInt32(nanos))
+	return m
+}
+
+// genFieldMask builds a FieldMask referencing a random subset of the
+// top-level field names of parent, the message this FieldMask field is
+// nested inside. If parent is nil (e.g. the FieldMask is the root message
+// being generated), it falls back to random strings.
+func (p *ProtoRand) genFieldMask(mds, parent protoreflect.MessageDescriptor) *dynamicpb.Message {
+	m := dynamicpb.NewMessage(mds)
+	pathsField := mds.Fields().ByName("paths")
+	list := m.Mutable(pathsField).List()
+
+	if parent == nil || parent.Fields().Len() == 0 {
+		count := p.randCount(p.config.listLenMin, p.config.listLenMax)
+		for i := 0; i < count; i++ {
+			list.Append(protoreflect.ValueOfString(p.randString()))
+		}
+		m.Set(pathsField, protoreflect.ValueOfList(list))
+		return m
+	}
+
+	fields := parent.Fields()
+	n := p.rand.Intn(fields.Len() + 1)
+	chosen := p.rand.Perm(fields.Len())[:n]
+	for _, idx := range chosen {
+		list.Append(protoreflect.ValueOfString(string(fields.Get(idx).Name())))
+	}
+	m.Set(pathsField, protoreflect.ValueOfList(list))
+	return m
+}
+
+// genAny picks a random message type from the configured registry, fills it
+// in recursively, and packs it into an Any.
+func (p *ProtoRand) genAny(mds protoreflect.MessageDescriptor, allowedDepth int) (*dynamicpb.Message, error) {
+	m := dynamicpb.NewMessage(mds)
+
+	var names []protoreflect.FullName
+	p.config.anyTypes.RangeMessages(func(mt protoreflect.MessageType) bool {
+		names = append(names, mt.Descriptor().FullName())
+		return true
+	})
+	if len(names) == 0 {
+		return m, nil
+	}
+	// RangeMessages order is not stable; sort so the pick depends only on p.rand
+	sort.Slice(names, func(i, j int) bool { return names[i] < names[j] })
+
+	mt, err := p.config.anyTypes.FindMessageByName(names[p.rand.Intn(len(names))])
+	if err != nil {
+		return nil, err
+	}
+
+	inner := allowedDepth - 1
+	if inner < 0 {
+		inner = 0
+	}
+	packed, err := p.newDynamicProtoRand(mt.Descriptor(), inner, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	b, err := proto.Marshal(packed)
+	if err != nil {
+		return nil, fmt.Errorf("protorand: marshal Any payload: %w", err)
+	}
+
+	m.Set(mds.Fields().ByName("type_url"), protoreflect.ValueOfString("type.googleapis.com/"+string(mt.Descriptor().FullName())))
+	m.Set(mds.Fields().ByName("value"), protoreflect.ValueOfBytes(b))
+	return m, nil
+}
+
+// genStruct generates a google.protobuf.Struct with a handful of random
+// fields, recursing into genValue with a shrinking depth budget.
+func (p *ProtoRand) genStruct(mds protoreflect.MessageDescriptor, allowedDepth int) *dynamicpb.Message {
+	m := dynamicpb.NewMessage(mds)
+	fieldsField := mds.Fields().ByName("fields")
+	mp := m.Mutable(fieldsField).Map()
+
+	if allowedDepth > 0 {
+		n := p.rand.Intn(3) + 1
+		for i := 0; i < n; i++ {
+			key := protoreflect.ValueOfString(p.randString()).MapKey()
+			mp.Set(key, protoreflect.ValueOfMessage(p.genValue(fieldsField.MapValue().Message(), allowedDepth-1)))
+		}
+	}
+	m.Set(fieldsField, protoreflect.ValueOfMap(mp))
+	return m
+}
+
+// genValue generates a google.protobuf.Value, picking one of its oneof kinds
+// at random. Once allowedDepth is exhausted only leaf kinds are produced.
+func (p *ProtoRand) genValue(mds protoreflect.MessageDescriptor, allowedDepth int) *dynamicpb.Message {
+	m := dynamicpb.NewMessage(mds)
+
+	kinds := []protoreflect.Name{"null_value", "number_value", "string_value", "bool_value"}
+	if allowedDepth > 0 {
+		kinds = append(kinds, "struct_value", "list_value")
+	}
+	switch kinds[p.rand.Intn(len(kinds))] {
+	case "null_value":
+		m.Set(mds.Fields().ByName("null_value"), protoreflect.ValueOfEnum(0))
+	case "number_value":
+		m.Set(mds.Fields().ByName("number_value"), protoreflect.ValueOfFloat64(p.randFloat64()))
+	case "string_value":
+		m.Set(mds.Fields().ByName("string_value"), protoreflect.ValueOfString(p.randString()))
+	case "bool_value":
+		m.Set(mds.Fields().ByName("bool_value"), protoreflect.ValueOfBool(p.randBool()))
+	case "struct_value":
+		structField := mds.Fields().ByName("struct_value")
+		m.Set(structField, protoreflect.ValueOfMessage(p.genStruct(structField.Message(), allowedDepth-1)))
+	case "list_value":
+		listField := mds.Fields().ByName("list_value")
+		m.Set(listField, protoreflect.ValueOfMessage(p.genListValue(listField.Message(), allowedDepth-1)))
+	}
+	return m
+}
+
+// genListValue generates a google.protobuf.ListValue with a handful of
+// random elements, recursing into genValue with a shrinking depth budget.
+func (p *ProtoRand) genListValue(mds protoreflect.MessageDescriptor, allowedDepth int) *dynamicpb.Message {
+	m := dynamicpb.NewMessage(mds)
+	valuesField := mds.Fields().ByName("values")
+	list := m.Mutable(valuesField).List()
+
+	if allowedDepth > 0 {
+		n := p.rand.Intn(3) + 1
+		for i := 0; i < n; i++ {
+			list.Append(protoreflect.ValueOfMessage(p.genValue(valuesField.Message(), allowedDepth-1)))
+		}
+	}
+	m.Set(valuesField, protoreflect.ValueOfList(list))
+	return m
+}
+
+// genWrapper generates one of the well-known wrapper types (StringValue,
+// Int32Value, etc.) by delegating to the primitive generator for its single
+// "value" field.
+func (p *ProtoRand) genWrapper(mds protoreflect.MessageDescriptor) (*dynamicpb.Message, error) {
+	m := dynamicpb.NewMessage(mds)
+	fd := mds.Fields().ByName("value")
+
+	var v protoreflect.Value
+	switch fd.Kind() {
+	case protoreflect.StringKind:
+		v = protoreflect.ValueOfString(p.randString())
+	case protoreflect.BytesKind:
+		v = protoreflect.ValueOfBytes(p.randBytes())
+	case protoreflect.BoolKind:
+		v = protoreflect.ValueOfBool(p.randBool())
+	case protoreflect.Int32Kind:
+		v = protoreflect.ValueOfInt32(p.randInt32())
+	case protoreflect.Int64Kind:
+		v = protoreflect.ValueOfInt64(p.randInt64())
+	case protoreflect.Uint32Kind:
+		v = protoreflect.ValueOfUint32(p.randUint32())
+	case protoreflect.Uint64Kind:
+		v = protoreflect.ValueOfUint64(p.randUint64())
+	case protoreflect.FloatKind:
+		v = protoreflect.ValueOfFloat32(p.randFloat32())
+	case protoreflect.DoubleKind:
+		v = protoreflect.ValueOfFloat64(p.randFloat64())
+	default:
+		return nil, fmt.Errorf("protorand: unexpected wrapper value kind: %v", fd.Kind())
+	}
+	m.Set(fd, v)
+	return m, nil
+}
@@ -0,0 +1,31 @@
+package protorand
+
+import (
+	"testing"
+
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestGenRootWellKnownType(t *testing.T) {
+	pr := New()
+	for i := 0; i < 200; i++ {
+		out, err := pr.Gen(&durationpb.Duration{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		d := out.(*durationpb.Duration)
+		if (d.Seconds < 0 && d.Nanos > 0) || (d.Seconds > 0 && d.Nanos < 0) {
+			t.Fatalf("Duration has mismatched signs: seconds=%d nanos=%d", d.Seconds, d.Nanos)
+		}
+	}
+
+	out, err := pr.Gen(&timestamppb.Timestamp{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := out.(*timestamppb.Timestamp)
+	if ts.Seconds < 1_000_000_000 || ts.Seconds > 2_000_000_000 {
+		t.Fatalf("Timestamp generated outside configured window: %d", ts.Seconds)
+	}
+}